@@ -0,0 +1,41 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// Backend abstracts how gitutil talks to a Git repository, so the same
+// package-level helpers work whether or not a `git` binary is installed.
+type Backend interface {
+	GetCurrentBranch(ctx context.Context, dir string) string
+	Status(ctx context.Context, dir string) (*RepoStatus, error)
+	Resolve(ctx context.Context, dir, ref string) (sha, typ string, size int64, err error)
+}
+
+// backendEnvVar overrides the automatic CLI-first Backend selection. Set it
+// to "cli" or "gogit" to force a specific implementation, e.g. in tests.
+const backendEnvVar = "CRUSH_GIT_BACKEND"
+
+const (
+	backendNameCLI   = "cli"
+	backendNameGoGit = "gogit"
+)
+
+// DefaultBackend returns the Backend used by gitutil's package-level
+// helpers: the git CLI when it is on PATH, falling back to the pure-Go
+// go-git implementation otherwise.
+func DefaultBackend() Backend {
+	switch os.Getenv(backendEnvVar) {
+	case backendNameCLI:
+		return cliBackend{}
+	case backendNameGoGit:
+		return gogitBackend{}
+	}
+
+	if _, err := exec.LookPath("git"); err == nil {
+		return cliBackend{}
+	}
+	return gogitBackend{}
+}
@@ -0,0 +1,26 @@
+package gitutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultBackend(t *testing.T) {
+	t.Run("honors CRUSH_GIT_BACKEND override", func(t *testing.T) {
+		t.Setenv(backendEnvVar, backendNameCLI)
+		require.IsType(t, cliBackend{}, DefaultBackend())
+
+		t.Setenv(backendEnvVar, backendNameGoGit)
+		require.IsType(t, gogitBackend{}, DefaultBackend())
+	})
+
+	t.Run("defaults to the CLI backend when git is on PATH", func(t *testing.T) {
+		if !gitAvailable() {
+			t.Skip("git is not available")
+		}
+		t.Setenv(backendEnvVar, "")
+
+		require.IsType(t, cliBackend{}, DefaultBackend())
+	})
+}
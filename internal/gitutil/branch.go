@@ -7,13 +7,20 @@ import (
 	"strings"
 )
 
-// GetCurrentBranch returns the current Git branch name for the given directory.
-// It uses the git CLI command to get the branch name.
+// GetCurrentBranch returns the current Git branch name for the given
+// directory, using the automatically-selected Backend (see DefaultBackend).
 // Returns an empty string if:
 // - The directory is not in a Git repository
 // - The repository is in a detached HEAD state
-// - Git is not installed or any error occurs
+// - Neither git nor a readable .git directory is available
 func GetCurrentBranch(ctx context.Context, dir string) string {
+	return DefaultBackend().GetCurrentBranch(ctx, dir)
+}
+
+// cliBackend implements Backend by shelling out to the git CLI.
+type cliBackend struct{}
+
+func (cliBackend) GetCurrentBranch(ctx context.Context, dir string) string {
 	if !isInsideWorkTree(ctx, dir) {
 		return ""
 	}
@@ -0,0 +1,207 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// gogitBackend implements Backend using go-git instead of shelling out to
+// the git CLI, for environments where the git binary is not installed.
+type gogitBackend struct{}
+
+func (gogitBackend) GetCurrentBranch(ctx context.Context, dir string) string {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+
+	return head.Name().Short()
+}
+
+func (gogitBackend) Status(ctx context.Context, dir string) (*RepoStatus, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("gitutil: opening repository: %w", err)
+	}
+
+	rs := &RepoStatus{}
+
+	head, err := repo.Head()
+	switch {
+	case err == nil:
+		rs.CommitSHA = head.Hash().String()[:7]
+		if head.Name().IsBranch() {
+			rs.Branch = head.Name().Short()
+		} else {
+			rs.Detached = true
+		}
+	case err == plumbing.ErrReferenceNotFound:
+		// Unborn branch: no commits yet.
+	default:
+		return nil, fmt.Errorf("gitutil: reading HEAD: %w", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("gitutil: opening worktree: %w", err)
+	}
+	fileStatus, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("gitutil: computing status: %w", err)
+	}
+	for _, fs := range fileStatus {
+		if fs.Worktree == git.Untracked {
+			rs.Untracked++
+			continue
+		}
+		if fs.Staging != git.Unmodified {
+			rs.Staged++
+		}
+		if fs.Worktree != git.Unmodified {
+			rs.Unstaged++
+		}
+	}
+
+	if rs.Branch != "" {
+		if cfg, err := repo.Config(); err == nil {
+			if branchCfg, ok := cfg.Branches[rs.Branch]; ok && branchCfg.Remote != "" && branchCfg.Merge != "" {
+				rs.Upstream = fmt.Sprintf("%s/%s", branchCfg.Remote, branchCfg.Merge.Short())
+
+				upstreamRef, err := repo.Reference(plumbing.NewRemoteReferenceName(branchCfg.Remote, branchCfg.Merge.Short()), true)
+				if err == nil && head != nil {
+					ahead, behind, err := countAheadBehind(repo, head.Hash(), upstreamRef.Hash())
+					if err == nil {
+						rs.Ahead, rs.Behind = ahead, behind
+					}
+				}
+			}
+		}
+	}
+
+	if gitDir, ok := gitDirOf(repo); ok {
+		rs.Merging, rs.Rebasing, rs.CherryPicking = inProgressState(gitDir)
+		rs.StashCount = stashCountFS(gitDir)
+	}
+
+	return rs, nil
+}
+
+// gitDirOf returns repo's actual .git directory, so callers don't have to
+// assume dir (passed to git.PlainOpenWithOptions) IS the git directory: dir
+// may be a subdirectory of the work tree, or a linked worktree, where .git is
+// a file pointing elsewhere entirely. It reports false if repo isn't backed
+// by an on-disk filesystem storer (e.g. it was opened in-memory).
+func gitDirOf(repo *git.Repository) (string, bool) {
+	fsStorer, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", false
+	}
+	return fsStorer.Filesystem().Root(), true
+}
+
+func (gogitBackend) Resolve(ctx context.Context, dir, ref string) (sha, typ string, size int64, err error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", "", 0, fmt.Errorf("gitutil: opening repository: %w", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("gitutil: resolving %q: %w", ref, err)
+	}
+
+	obj, err := repo.Storer.EncodedObject(plumbing.AnyObject, *hash)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("gitutil: reading object %q: %w", ref, err)
+	}
+
+	return hash.String(), obj.Type().String(), obj.Size(), nil
+}
+
+// countAheadBehind walks the full ancestry of local and upstream and counts
+// commits reachable from one but not the other.
+func countAheadBehind(repo *git.Repository, local, upstream plumbing.Hash) (ahead, behind int, err error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	localAncestors, err := commitAncestry(repo, local)
+	if err != nil {
+		return 0, 0, err
+	}
+	upstreamAncestors, err := commitAncestry(repo, upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for h := range localAncestors {
+		if _, ok := upstreamAncestors[h]; !ok {
+			ahead++
+		}
+	}
+	for h := range upstreamAncestors {
+		if _, ok := localAncestors[h]; !ok {
+			behind++
+		}
+	}
+
+	return ahead, behind, nil
+}
+
+// commitAncestry returns the set of commit hashes reachable from start.
+func commitAncestry(repo *git.Repository, start plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	seen := map[plumbing.Hash]struct{}{}
+
+	queue := []plumbing.Hash{start}
+	for len(queue) > 0 {
+		h := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+
+		commit, err := repo.CommitObject(h)
+		if err != nil {
+			return nil, err
+		}
+		err = commit.Parents().ForEach(func(p *object.Commit) error {
+			queue = append(queue, p.Hash)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return seen, nil
+}
+
+// stashCountFS counts stash entries by reading the stash reflog directly out
+// of gitDir (the repository's actual .git directory, see gitDirOf), since
+// go-git has no high-level stash listing API.
+func stashCountFS(gitDir string) int {
+	data, err := os.ReadFile(filepath.Join(gitDir, "logs", "refs", "stash"))
+	if err != nil {
+		return 0
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return 0
+	}
+	return strings.Count(trimmed, "\n") + 1
+}
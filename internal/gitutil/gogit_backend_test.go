@@ -0,0 +1,51 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGogitBackendStatus(t *testing.T) {
+	if !gitAvailable() {
+		t.Skip("git is not available")
+	}
+
+	ctx := context.Background()
+
+	t.Run("reports merge in progress from a subdirectory", func(t *testing.T) {
+		testDir := t.TempDir()
+		runGit(t, testDir, "init")
+		runGit(t, testDir, "config", "user.email", "test@test.com")
+		runGit(t, testDir, "config", "user.name", "Test User")
+		runGit(t, testDir, "checkout", "-b", "main")
+
+		testFile := filepath.Join(testDir, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("base"), 0o644))
+		runGit(t, testDir, "add", ".")
+		runGit(t, testDir, "commit", "-m", "initial commit")
+		runGit(t, testDir, "checkout", "-b", "feature")
+
+		require.NoError(t, os.WriteFile(testFile, []byte("feature"), 0o644))
+		runGit(t, testDir, "commit", "-am", "feature commit")
+
+		runGit(t, testDir, "checkout", "main")
+		require.NoError(t, os.WriteFile(testFile, []byte("main"), 0o644))
+		runGit(t, testDir, "commit", "-am", "main commit")
+
+		subDir := filepath.Join(testDir, "src", "internal")
+		require.NoError(t, os.MkdirAll(subDir, 0o755))
+
+		mergeCmd := exec.Command("git", "merge", "feature")
+		mergeCmd.Dir = testDir
+		_ = mergeCmd.Run() // expected to conflict
+
+		status, err := gogitBackend{}.Status(ctx, subDir)
+		require.NoError(t, err)
+		require.True(t, status.Merging)
+	})
+}
@@ -0,0 +1,211 @@
+package gitutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"iter"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GrepOptions configures a Grep call.
+type GrepOptions struct {
+	// Pattern is the search pattern.
+	Pattern string
+	// FixedString treats Pattern (and And/Or) as literal strings instead of
+	// regexes, like `git grep -F`.
+	FixedString bool
+	// IgnoreCase makes the match case-insensitive.
+	IgnoreCase bool
+	// And/Or add additional patterns that must all match (And) or any of
+	// which may match (Or), mirroring `git grep --and`/`--or`.
+	And []string
+	Or  []string
+	// PathSpecs restricts the search to matching paths, e.g. "*.go".
+	PathSpecs []string
+	// Ref greps a specific revision instead of the worktree.
+	Ref string
+	// Context is the number of lines of context to include before and after
+	// each match, like `git grep -C N`.
+	Context int
+}
+
+// GrepHit is a single match produced by Grep.
+type GrepHit struct {
+	Path   string
+	Line   int
+	Text   string
+	Before []string
+	After  []string
+}
+
+// grepLine is one path/line/text record parsed out of `git grep -n -z`
+// output, match or context alike.
+type grepLine struct {
+	Path string
+	Line int
+	Text string
+}
+
+// Grep runs `git grep` over dir and yields one GrepHit per match. It respects
+// .gitignore and submodule boundaries the same way `git grep` does, and is
+// typically much faster than walking the filesystem. Iteration stops and
+// yields a single error if the underlying git invocation fails.
+//
+// When opts.Context is 0, Grep issues a single `git grep -n -z` call. Git's
+// -z output does not distinguish match lines from context lines by
+// separator (both use NUL), so when opts.Context > 0, Grep issues a second,
+// context-free call to learn exactly which lines matched, and uses it to
+// split each contextual hunk from the first call into GrepHits.
+func Grep(ctx context.Context, dir string, opts GrepOptions) iter.Seq2[GrepHit, error] {
+	return func(yield func(GrepHit, error) bool) {
+		hunks, err := runGitGrep(ctx, dir, opts, opts.Context)
+		if err != nil {
+			yield(GrepHit{}, err)
+			return
+		}
+
+		if opts.Context == 0 {
+			for _, hunk := range hunks {
+				for _, l := range hunk {
+					if !yield(GrepHit{Path: l.Path, Line: l.Line, Text: l.Text}, nil) {
+						return
+					}
+				}
+			}
+			return
+		}
+
+		matchLines, err := runGitGrep(ctx, dir, opts, 0)
+		if err != nil {
+			yield(GrepHit{}, err)
+			return
+		}
+		isMatch := make(map[string]bool, len(matchLines))
+		for _, hunk := range matchLines {
+			for _, l := range hunk {
+				isMatch[l.Path+"\x00"+strconv.Itoa(l.Line)] = true
+			}
+		}
+
+		for _, hunk := range hunks {
+			for i, l := range hunk {
+				if !isMatch[l.Path+"\x00"+strconv.Itoa(l.Line)] {
+					continue
+				}
+
+				hit := GrepHit{Path: l.Path, Line: l.Line, Text: l.Text}
+				for j := i - 1; j >= 0 && hunk[j].Path == l.Path && hunk[j].Line < l.Line; j-- {
+					if isMatch[hunk[j].Path+"\x00"+strconv.Itoa(hunk[j].Line)] {
+						break
+					}
+					hit.Before = append([]string{hunk[j].Text}, hit.Before...)
+				}
+				for j := i + 1; j < len(hunk) && hunk[j].Path == l.Path; j++ {
+					if isMatch[hunk[j].Path+"\x00"+strconv.Itoa(hunk[j].Line)] {
+						break
+					}
+					hit.After = append(hit.After, hunk[j].Text)
+				}
+
+				if !yield(hit, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// runGitGrep runs `git grep` with the given context width and returns the
+// output grouped into hunks (contiguous runs of lines, as separated by
+// git's own "--" hunk marker).
+func runGitGrep(ctx context.Context, dir string, opts GrepOptions, contextLines int) ([][]grepLine, error) {
+	args := buildGrepArgs(opts, contextLines)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		// git grep exits 1 when there are simply no matches.
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gitutil: git grep: %w", err)
+	}
+
+	return parseGrepOutput(output)
+}
+
+func buildGrepArgs(opts GrepOptions, contextLines int) []string {
+	args := []string{"grep", "-n", "-z", "--full-name", "--no-color", "-I"}
+
+	if opts.FixedString {
+		args = append(args, "-F")
+	}
+	if opts.IgnoreCase {
+		args = append(args, "-i")
+	}
+	if contextLines > 0 {
+		args = append(args, "-C", strconv.Itoa(contextLines))
+	}
+
+	args = append(args, "-e", opts.Pattern)
+	for _, p := range opts.And {
+		args = append(args, "--and", "-e", p)
+	}
+	for _, p := range opts.Or {
+		args = append(args, "--or", "-e", p)
+	}
+
+	if opts.Ref != "" {
+		args = append(args, opts.Ref)
+	}
+
+	if len(opts.PathSpecs) > 0 {
+		args = append(args, "--")
+		args = append(args, opts.PathSpecs...)
+	}
+
+	return args
+}
+
+// parseGrepOutput parses the \0-delimited output of `git grep -n -z`: each
+// record is "path\0line\0text", terminated by a real newline; hunks (runs of
+// adjacent lines from -C) are separated by a literal "--\n" line.
+func parseGrepOutput(output []byte) ([][]grepLine, error) {
+	var hunks [][]grepLine
+	var current []grepLine
+
+	sc := bufio.NewScanner(strings.NewReader(string(output)))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "--" {
+			if len(current) > 0 {
+				hunks = append(hunks, current)
+				current = nil
+			}
+			continue
+		}
+
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		lineNum, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		current = append(current, grepLine{Path: fields[0], Line: lineNum, Text: fields[2]})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("gitutil: parsing git grep output: %w", err)
+	}
+	if len(current) > 0 {
+		hunks = append(hunks, current)
+	}
+
+	return hunks, nil
+}
@@ -0,0 +1,110 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupGrepRepo(t *testing.T) string {
+	t.Helper()
+	testDir := t.TempDir()
+
+	runGit(t, testDir, "init")
+	runGit(t, testDir, "config", "user.email", "test@test.com")
+	runGit(t, testDir, "config", "user.name", "Test User")
+
+	content := "line1\nline2\nneedle here\nline4\nline5\n"
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "a.txt"), []byte(content), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "b.md"), []byte("no match\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, ".gitignore"), []byte("ignored.txt\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "ignored.txt"), []byte("needle here too\n"), 0o644))
+
+	runGit(t, testDir, "add", ".")
+	runGit(t, testDir, "commit", "-m", "initial commit")
+
+	return testDir
+}
+
+func collectHits(t *testing.T, seq func(func(GrepHit, error) bool)) []GrepHit {
+	t.Helper()
+	var hits []GrepHit
+	for hit, err := range seq {
+		require.NoError(t, err)
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+func TestGrep(t *testing.T) {
+	if !gitAvailable() {
+		t.Skip("git is not available")
+	}
+
+	ctx := context.Background()
+	testDir := setupGrepRepo(t)
+
+	t.Run("finds matches and respects gitignore", func(t *testing.T) {
+		hits := collectHits(t, Grep(ctx, testDir, GrepOptions{Pattern: "needle"}))
+		require.Len(t, hits, 1)
+		require.Equal(t, "a.txt", hits[0].Path)
+		require.Equal(t, 3, hits[0].Line)
+		require.Equal(t, "needle here", hits[0].Text)
+	})
+
+	t.Run("returns no hits and no error for no matches", func(t *testing.T) {
+		hits := collectHits(t, Grep(ctx, testDir, GrepOptions{Pattern: "does-not-exist"}))
+		require.Empty(t, hits)
+	})
+
+	t.Run("respects path restrictions", func(t *testing.T) {
+		hits := collectHits(t, Grep(ctx, testDir, GrepOptions{Pattern: "no match", PathSpecs: []string{"*.md"}}))
+		require.Len(t, hits, 1)
+		require.Equal(t, "b.md", hits[0].Path)
+	})
+
+	t.Run("is case-insensitive when requested", func(t *testing.T) {
+		hits := collectHits(t, Grep(ctx, testDir, GrepOptions{Pattern: "NEEDLE", IgnoreCase: true}))
+		require.Len(t, hits, 1)
+	})
+
+	t.Run("includes surrounding context lines", func(t *testing.T) {
+		hits := collectHits(t, Grep(ctx, testDir, GrepOptions{Pattern: "needle", Context: 1}))
+		require.Len(t, hits, 1)
+		require.Equal(t, []string{"line2"}, hits[0].Before)
+		require.Equal(t, []string{"line4"}, hits[0].After)
+	})
+
+	t.Run("keeps context scoped to each match when matches are close together", func(t *testing.T) {
+		nearDir := t.TempDir()
+		runGit(t, nearDir, "init")
+		runGit(t, nearDir, "config", "user.email", "test@test.com")
+		runGit(t, nearDir, "config", "user.name", "Test User")
+
+		content := "l1\nneedle1\nl3\nneedle2\nl5\n"
+		require.NoError(t, os.WriteFile(filepath.Join(nearDir, "n.txt"), []byte(content), 0o644))
+		runGit(t, nearDir, "add", ".")
+		runGit(t, nearDir, "commit", "-m", "initial commit")
+
+		hits := collectHits(t, Grep(ctx, nearDir, GrepOptions{Pattern: "needle", Context: 2}))
+		require.Len(t, hits, 2)
+
+		require.Equal(t, 2, hits[0].Line)
+		require.Equal(t, []string{"l1"}, hits[0].Before)
+		require.Equal(t, []string{"l3"}, hits[0].After)
+
+		require.Equal(t, 4, hits[1].Line)
+		require.Equal(t, []string{"l3"}, hits[1].Before)
+		require.Equal(t, []string{"l5"}, hits[1].After)
+	})
+
+	t.Run("can grep a specific ref", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(testDir, "a.txt"), []byte("changed\n"), 0o644))
+
+		hits := collectHits(t, Grep(ctx, testDir, GrepOptions{Pattern: "needle", Ref: "HEAD"}))
+		require.Len(t, hits, 1)
+	})
+}
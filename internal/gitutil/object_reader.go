@@ -0,0 +1,230 @@
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ObjectReader gives cheap, repeated access to Git objects (commits, trees,
+// blobs) by keeping a single long-lived `git cat-file --batch` process
+// running instead of spawning a new `git` process per lookup.
+//
+// An ObjectReader is safe for concurrent use; callers are serialized behind
+// an internal mutex since the underlying process is a single request/response
+// pipe.
+type ObjectReader struct {
+	dir string
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	out     *bufio.Reader
+	dead    atomic.Bool // set once the subprocess has exited, for any reason
+	done    chan struct{}
+	waitErr error // cmd.Wait()'s result, valid once done is closed
+}
+
+// NewObjectReader starts a `git cat-file --batch` process rooted at dir. The
+// caller must call Close when done.
+func NewObjectReader(ctx context.Context, dir string) (*ObjectReader, error) {
+	r := &ObjectReader{dir: dir}
+	if err := r.spawn(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *ObjectReader) spawn(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	cmd.Dir = r.dir
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("gitutil: cat-file stdin pipe: %w", err)
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("gitutil: cat-file stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("gitutil: starting cat-file --batch: %w", err)
+	}
+
+	r.cmd = cmd
+	r.in = in
+	r.out = bufio.NewReader(out)
+	r.dead.Store(false)
+	done := make(chan struct{})
+	r.done = done
+
+	// cmd.ProcessState is only populated once Wait returns, so a dedicated
+	// goroutine is needed to notice the subprocess exiting on its own (OOM
+	// kill, crash, ...) instead of only when Close calls Wait.
+	go func() {
+		err := cmd.Wait()
+		r.waitErr = err
+		r.dead.Store(true)
+		close(done)
+	}()
+
+	return nil
+}
+
+// ensureAlive respawns the subprocess if it has died since the last call.
+func (r *ObjectReader) ensureAlive(ctx context.Context) error {
+	if r.cmd != nil && !r.dead.Load() {
+		return nil
+	}
+	return r.spawn(ctx)
+}
+
+// request writes ref followed by a newline and reads back the header line
+// plus, if the object exists, its full body (`git cat-file --batch` always
+// streams the body after the header, whether or not the caller wants it, so
+// it must always be drained here — otherwise it desyncs the stream and every
+// later request on this ObjectReader reads garbage). It returns the parsed
+// sha/type/size, the object's content, and whether the object is missing.
+func (r *ObjectReader) request(ctx context.Context, ref string) (sha, typ string, size int64, content []byte, missing bool, err error) {
+	if err := r.ensureAlive(ctx); err != nil {
+		return "", "", 0, nil, false, err
+	}
+
+	if _, err := fmt.Fprintf(r.in, "%s\n", ref); err != nil {
+		r.dead.Store(true)
+		return "", "", 0, nil, false, fmt.Errorf("gitutil: writing to cat-file --batch: %w", err)
+	}
+
+	line, err := r.out.ReadString('\n')
+	if err != nil {
+		r.dead.Store(true)
+		return "", "", 0, nil, false, fmt.Errorf("gitutil: reading cat-file --batch header: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+
+	fields := strings.Fields(line)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return "", "", 0, nil, true, nil
+	}
+	if len(fields) != 3 {
+		return "", "", 0, nil, false, fmt.Errorf("gitutil: unexpected cat-file --batch header %q", line)
+	}
+
+	size, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return "", "", 0, nil, false, fmt.Errorf("gitutil: parsing object size: %w", err)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r.out, buf); err != nil {
+		r.dead.Store(true)
+		return "", "", 0, nil, false, fmt.Errorf("gitutil: reading object content: %w", err)
+	}
+	// Content is followed by a trailing newline.
+	if _, err := r.out.Discard(1); err != nil {
+		r.dead.Store(true)
+		return "", "", 0, nil, false, fmt.Errorf("gitutil: reading object trailer: %w", err)
+	}
+
+	return fields[0], fields[1], size, buf, false, nil
+}
+
+// Resolve resolves ref to its full SHA, type and size. The object's content
+// is still read off the pipe (see request) but is discarded, not returned.
+func (r *ObjectReader) Resolve(ctx context.Context, ref string) (sha, typ string, size int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sha, typ, size, _, missing, err := r.request(ctx, ref)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if missing {
+		return "", "", 0, fmt.Errorf("gitutil: object %q not found", ref)
+	}
+	return sha, typ, size, nil
+}
+
+// Exists reports whether ref resolves to an object in the repository.
+func (r *ObjectReader) Exists(ctx context.Context, ref string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, _, _, _, missing, err := r.request(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	return !missing, nil
+}
+
+// ReadObject reads the full content of ref. The content is drained from the
+// shared `git cat-file --batch` pipe before ReadObject returns, so the
+// returned reader is independent of it: callers are free to read it
+// partially, out of order, or not at all without blocking or corrupting
+// later ObjectReader calls.
+func (r *ObjectReader) ReadObject(ctx context.Context, ref string) (typ string, size int64, body io.Reader, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, typ, size, content, missing, err := r.request(ctx, ref)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if missing {
+		return "", 0, nil, fmt.Errorf("gitutil: object %q not found", ref)
+	}
+
+	return typ, size, bytes.NewReader(content), nil
+}
+
+// Close terminates the underlying `git cat-file --batch` process.
+func (r *ObjectReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cmd == nil {
+		return nil
+	}
+	_ = r.in.Close()
+	<-r.done // the spawn goroutine calls cmd.Wait(); don't call it twice
+	err := r.waitErr
+	r.cmd = nil
+	return err
+}
+
+// Resolve resolves ref to its full SHA, type and size using a one-shot
+// `git cat-file --batch-check` call. Prefer ObjectReader.Resolve over this
+// when resolving many refs in a row.
+func (cliBackend) Resolve(ctx context.Context, dir, ref string) (sha, typ string, size int64, err error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch-check")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(ref + "\n")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", 0, fmt.Errorf("gitutil: git cat-file --batch-check: %w", err)
+	}
+
+	line := strings.TrimSpace(string(output))
+	fields := strings.Fields(line)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return "", "", 0, fmt.Errorf("gitutil: object %q not found", ref)
+	}
+	if len(fields) != 3 {
+		return "", "", 0, fmt.Errorf("gitutil: unexpected cat-file --batch-check output %q", line)
+	}
+
+	size, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("gitutil: parsing object size: %w", err)
+	}
+
+	return fields[0], fields[1], size, nil
+}
@@ -0,0 +1,131 @@
+package gitutil
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestObjectReader(t *testing.T, ctx context.Context, dir string) *ObjectReader {
+	t.Helper()
+	r, err := NewObjectReader(ctx, dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestObjectReader(t *testing.T) {
+	if !gitAvailable() {
+		t.Skip("git is not available")
+	}
+
+	ctx := context.Background()
+	testDir := t.TempDir()
+
+	runGit(t, testDir, "init")
+	runGit(t, testDir, "config", "user.email", "test@test.com")
+	runGit(t, testDir, "config", "user.name", "Test User")
+
+	blobPath := filepath.Join(testDir, "hello.txt")
+	require.NoError(t, os.WriteFile(blobPath, []byte("hello, world\n"), 0o644))
+	runGit(t, testDir, "add", ".")
+	runGit(t, testDir, "commit", "-m", "initial commit")
+
+	reader := newTestObjectReader(t, ctx, testDir)
+
+	t.Run("resolves HEAD to a commit", func(t *testing.T) {
+		sha, typ, size, err := reader.Resolve(ctx, "HEAD")
+		require.NoError(t, err)
+		require.Len(t, sha, 40)
+		require.Equal(t, "commit", typ)
+		require.Positive(t, size)
+	})
+
+	t.Run("reads a blob's content", func(t *testing.T) {
+		typ, size, body, err := reader.ReadObject(ctx, "HEAD:hello.txt")
+		require.NoError(t, err)
+		require.Equal(t, "blob", typ)
+		require.EqualValues(t, len("hello, world\n"), size)
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, "hello, world\n", string(data))
+	})
+
+	t.Run("exists reports missing refs", func(t *testing.T) {
+		ok, err := reader.Exists(ctx, "refs/heads/does-not-exist")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = reader.Exists(ctx, "HEAD")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("serializes repeated calls after a read", func(t *testing.T) {
+		_, _, body, err := reader.ReadObject(ctx, "HEAD:hello.txt")
+		require.NoError(t, err)
+		_, err = io.ReadAll(body)
+		require.NoError(t, err)
+
+		sha, _, _, err := reader.Resolve(ctx, "HEAD")
+		require.NoError(t, err)
+		require.Len(t, sha, 40)
+	})
+
+	t.Run("a partially-read body does not wedge later calls", func(t *testing.T) {
+		_, _, body, err := reader.ReadObject(ctx, "HEAD:hello.txt")
+		require.NoError(t, err)
+
+		// Only read the first byte; ReadObject must have already drained
+		// the rest of the object (and its trailing newline) off the shared
+		// pipe before returning, so this must not affect later calls.
+		one := make([]byte, 1)
+		_, err = body.Read(one)
+		require.NoError(t, err)
+
+		sha, _, _, err := reader.Resolve(ctx, "HEAD")
+		require.NoError(t, err)
+		require.Len(t, sha, 40)
+	})
+
+	t.Run("resolve and exists drain the object body so later calls stay in sync", func(t *testing.T) {
+		// cat-file --batch always streams the full body after the header,
+		// whether or not the caller asked for content. Resolve/Exists must
+		// drain it themselves or every later call on this reader desyncs.
+		for i := 0; i < 3; i++ {
+			sha, typ, _, err := reader.Resolve(ctx, "HEAD")
+			require.NoError(t, err)
+			require.Len(t, sha, 40)
+			require.Equal(t, "commit", typ)
+
+			ok, err := reader.Exists(ctx, "HEAD")
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		typ, _, body, err := reader.ReadObject(ctx, "HEAD:hello.txt")
+		require.NoError(t, err)
+		require.Equal(t, "blob", typ)
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, "hello, world\n", string(data))
+	})
+
+	t.Run("respawns after the subprocess dies unexpectedly", func(t *testing.T) {
+		reader.mu.Lock()
+		require.NoError(t, reader.cmd.Process.Kill())
+		done := reader.done
+		reader.mu.Unlock()
+
+		<-done // wait for the spawn goroutine to notice the exit
+
+		sha, _, _, err := reader.Resolve(ctx, "HEAD")
+		require.NoError(t, err)
+		require.Len(t, sha, 40)
+	})
+}
@@ -0,0 +1,90 @@
+package gitutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"iter"
+	"os/exec"
+	"strings"
+)
+
+// Field identifies a `git for-each-ref` format placeholder.
+type Field string
+
+const (
+	FieldRefName       Field = "refname"
+	FieldRefNameShort  Field = "refname:short"
+	FieldObjectName    Field = "objectname"
+	FieldUpstreamShort Field = "upstream:short"
+	FieldUpstreamTrack Field = "upstream:track"
+	FieldCommitterDate Field = "committerdate:iso8601"
+	FieldSubject       Field = "subject"
+	FieldHEAD          Field = "HEAD"
+)
+
+// Ref is one record produced by ForEachRef, with one entry per requested
+// Field, keyed by that Field.
+type Ref map[Field]string
+
+// fieldSep separates placeholders within a single for-each-ref format
+// string. It can't be a NUL byte: the format is passed as a process
+// argument, and argv strings can't contain NUL. Records themselves are
+// separated by the newline git already emits after each formatted ref, so no
+// record separator is needed; every Field this package supports (refnames,
+// ISO 8601 dates, subjects, ...) is single-line by construction.
+const fieldSep = "\x01"
+
+// ForEachRef runs `git for-each-ref` over patterns and yields one Ref per
+// matching ref, populated with the requested fields. Iteration stops and
+// yields a single error if the underlying git invocation fails.
+func ForEachRef(ctx context.Context, dir string, patterns []string, fields []Field) iter.Seq2[Ref, error] {
+	return func(yield func(Ref, error) bool) {
+		format := buildForEachRefFormat(fields)
+
+		args := append([]string{"for-each-ref", "--format=" + format}, patterns...)
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+
+		output, err := cmd.Output()
+		if err != nil {
+			yield(nil, fmt.Errorf("gitutil: git for-each-ref: %w", err))
+			return
+		}
+
+		sc := bufio.NewScanner(strings.NewReader(string(output)))
+		for sc.Scan() {
+			line := sc.Text()
+			if line == "" {
+				continue
+			}
+
+			values := strings.Split(line, fieldSep)
+			if len(values) != len(fields) {
+				yield(nil, fmt.Errorf("gitutil: for-each-ref record has %d fields, want %d", len(values), len(fields)))
+				return
+			}
+
+			ref := make(Ref, len(fields))
+			for i, f := range fields {
+				ref[f] = values[i]
+			}
+			if !yield(ref, nil) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			yield(nil, fmt.Errorf("gitutil: reading for-each-ref output: %w", err))
+		}
+	}
+}
+
+// buildForEachRefFormat turns fields into a `--format` string, joining
+// placeholders with fieldSep.
+func buildForEachRefFormat(fields []Field) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = "%(" + string(f) + ")"
+	}
+	return strings.Join(parts, fieldSep)
+}
@@ -0,0 +1,57 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachRef(t *testing.T) {
+	if !gitAvailable() {
+		t.Skip("git is not available")
+	}
+
+	ctx := context.Background()
+	testDir := t.TempDir()
+
+	runGit(t, testDir, "init")
+	runGit(t, testDir, "config", "user.email", "test@test.com")
+	runGit(t, testDir, "config", "user.name", "Test User")
+	runGit(t, testDir, "checkout", "-b", "main")
+
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "test.txt"), []byte("test"), 0o644))
+	runGit(t, testDir, "add", ".")
+	runGit(t, testDir, "commit", "-m", "initial commit")
+	runGit(t, testDir, "branch", "feature/one")
+	runGit(t, testDir, "tag", "v1.0.0")
+
+	t.Run("lists refname and refname:short", func(t *testing.T) {
+		var names []string
+		for ref, err := range ForEachRef(ctx, testDir, []string{"refs/heads", "refs/tags"}, []Field{FieldRefName, FieldRefNameShort}) {
+			require.NoError(t, err)
+			names = append(names, ref[FieldRefNameShort])
+		}
+		require.ElementsMatch(t, []string{"main", "feature/one", "v1.0.0"}, names)
+	})
+
+	t.Run("includes objectname and subject", func(t *testing.T) {
+		for ref, err := range ForEachRef(ctx, testDir, []string{"refs/heads/main"}, []Field{FieldRefNameShort, FieldObjectName, FieldSubject}) {
+			require.NoError(t, err)
+			require.Equal(t, "main", ref[FieldRefNameShort])
+			require.Len(t, ref[FieldObjectName], 40)
+			require.Equal(t, "initial commit", ref[FieldSubject])
+		}
+	})
+
+	t.Run("stops early when the consumer stops iterating", func(t *testing.T) {
+		count := 0
+		for range ForEachRef(ctx, testDir, []string{"refs/heads", "refs/tags"}, []Field{FieldRefNameShort}) {
+			count++
+			break
+		}
+		require.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,208 @@
+package gitutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RepoStatus is a snapshot of a Git repository's branch and working tree
+// state, similar to what the Status/Branches panels in lazygit show.
+type RepoStatus struct {
+	Branch    string // current branch name, empty if detached
+	Detached  bool
+	CommitSHA string // short SHA of HEAD
+	Upstream  string // upstream tracking ref, empty if none
+
+	Ahead  int
+	Behind int
+
+	Staged    int
+	Unstaged  int
+	Untracked int
+
+	Merging       bool
+	Rebasing      bool
+	CherryPicking bool
+
+	StashCount int
+}
+
+// Status returns a RepoStatus for dir, using the automatically-selected
+// Backend (see DefaultBackend).
+func Status(ctx context.Context, dir string) (*RepoStatus, error) {
+	return DefaultBackend().Status(ctx, dir)
+}
+
+// Status issues a single `git status --porcelain=v2 --branch` call for
+// branch and file state, and a `git rev-list --left-right --count` call to
+// compute ahead/behind against the upstream, if any.
+func (cliBackend) Status(ctx context.Context, dir string) (*RepoStatus, error) {
+	if !isInsideWorkTree(ctx, dir) {
+		return nil, fmt.Errorf("gitutil: %s is not inside a git work tree", dir)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain=v2", "--branch")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gitutil: git status: %w", err)
+	}
+
+	status, err := ParsePorcelainV2(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	if status.Upstream != "" {
+		ahead, behind, err := revListAheadBehind(ctx, dir, status.Upstream)
+		if err == nil {
+			status.Ahead, status.Behind = ahead, behind
+		}
+	}
+
+	if gd, err := resolveGitDir(ctx, dir); err == nil {
+		status.Merging, status.Rebasing, status.CherryPicking = inProgressState(gd)
+	}
+	status.StashCount = stashCount(ctx, dir)
+
+	return status, nil
+}
+
+// resolveGitDir returns the actual .git directory for dir. This is not
+// necessarily filepath.Join(dir, ".git"): dir may be a subdirectory of the
+// worktree (which GetCurrentBranch explicitly supports), or a linked
+// worktree, where .git is a file pointing elsewhere entirely.
+func resolveGitDir(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gitutil: git rev-parse --absolute-git-dir: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ParsePorcelainV2 parses the output of `git status --porcelain=v2 --branch`
+// into a RepoStatus. It is kept separate from Status so the parsing logic can
+// be unit-tested without spawning git. It does not populate Ahead, Behind,
+// Merging, Rebasing, CherryPicking or StashCount, which require additional
+// git invocations; see Status.
+func ParsePorcelainV2(output string) (*RepoStatus, error) {
+	rs := &RepoStatus{}
+
+	sc := bufio.NewScanner(strings.NewReader(output))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			sha := strings.TrimPrefix(line, "# branch.oid ")
+			if sha != "(initial)" && len(sha) >= 7 {
+				rs.CommitSHA = sha[:7]
+			}
+		case strings.HasPrefix(line, "# branch.head "):
+			head := strings.TrimPrefix(line, "# branch.head ")
+			if head == "(detached)" {
+				rs.Detached = true
+			} else {
+				rs.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			rs.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// Ahead/behind are computed separately via `git rev-list`; the
+			// counts on this line are ignored.
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "):
+			fields := strings.SplitN(line, " ", 3)
+			if len(fields) < 2 || len(fields[1]) != 2 {
+				continue
+			}
+			xy := fields[1]
+			if xy[0] != '.' {
+				rs.Staged++
+			}
+			if xy[1] != '.' {
+				rs.Unstaged++
+			}
+		case strings.HasPrefix(line, "u "):
+			// Unmerged entries have conflicting changes in both the index
+			// and the worktree.
+			rs.Staged++
+			rs.Unstaged++
+		case strings.HasPrefix(line, "? "):
+			rs.Untracked++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("gitutil: parsing porcelain v2 output: %w", err)
+	}
+
+	return rs, nil
+}
+
+// revListAheadBehind returns how many commits HEAD is ahead of and behind
+// upstream.
+func revListAheadBehind(ctx context.Context, dir, upstream string) (ahead, behind int, err error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--left-right", "--count", upstream+"...HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("gitutil: git rev-list: %w", err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("gitutil: unexpected rev-list output %q", output)
+	}
+
+	behind, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("gitutil: parsing behind count: %w", err)
+	}
+	ahead, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("gitutil: parsing ahead count: %w", err)
+	}
+
+	return ahead, behind, nil
+}
+
+// inProgressState reports whether gitDir (the repository's actual .git
+// directory, see resolveGitDir) has a merge, rebase or cherry-pick in
+// progress, based on the presence of the marker files Git itself uses.
+func inProgressState(gitDir string) (merging, rebasing, cherryPicking bool) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+
+	merging = exists("MERGE_HEAD")
+	rebasing = exists("rebase-merge") || exists("rebase-apply")
+	cherryPicking = exists("CHERRY_PICK_HEAD")
+	return merging, rebasing, cherryPicking
+}
+
+// stashCount returns the number of entries in the stash.
+func stashCount(ctx context.Context, dir string) int {
+	cmd := exec.CommandContext(ctx, "git", "stash", "list")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return 0
+	}
+	return strings.Count(trimmed, "\n") + 1
+}
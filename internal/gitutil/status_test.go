@@ -0,0 +1,200 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePorcelainV2(t *testing.T) {
+	t.Run("clean branch tracking upstream", func(t *testing.T) {
+		output := "# branch.oid abcdef1234567890\n" +
+			"# branch.head main\n" +
+			"# branch.upstream origin/main\n" +
+			"# branch.ab +0 -0\n"
+
+		rs, err := ParsePorcelainV2(output)
+		require.NoError(t, err)
+		require.Equal(t, "main", rs.Branch)
+		require.False(t, rs.Detached)
+		require.Equal(t, "abcdef1", rs.CommitSHA)
+		require.Equal(t, "origin/main", rs.Upstream)
+		require.Zero(t, rs.Staged)
+		require.Zero(t, rs.Unstaged)
+		require.Zero(t, rs.Untracked)
+	})
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		output := "# branch.oid abcdef1234567890\n" +
+			"# branch.head (detached)\n"
+
+		rs, err := ParsePorcelainV2(output)
+		require.NoError(t, err)
+		require.Empty(t, rs.Branch)
+		require.True(t, rs.Detached)
+	})
+
+	t.Run("staged, unstaged, untracked and unmerged entries", func(t *testing.T) {
+		output := "# branch.oid abcdef1234567890\n" +
+			"# branch.head main\n" +
+			"1 M. N... 100644 100644 100644 aaaaaaa bbbbbbb staged.txt\n" +
+			"1 .M N... 100644 100644 100644 aaaaaaa aaaaaaa unstaged.txt\n" +
+			"1 MM N... 100644 100644 100644 aaaaaaa ccccccc both.txt\n" +
+			"2 R. N... 100644 100644 100644 aaaaaaa ddddddd R100 renamed.txt\tsep\told.txt\n" +
+			"u UU N... 100644 100644 100644 100644 aaaaaaa bbbbbbb ccccccc conflict.txt\n" +
+			"? new.txt\n"
+
+		rs, err := ParsePorcelainV2(output)
+		require.NoError(t, err)
+		require.Equal(t, 4, rs.Staged)
+		require.Equal(t, 3, rs.Unstaged)
+		require.Equal(t, 1, rs.Untracked)
+	})
+
+	t.Run("initial commit has no SHA", func(t *testing.T) {
+		output := "# branch.oid (initial)\n" +
+			"# branch.head main\n"
+
+		rs, err := ParsePorcelainV2(output)
+		require.NoError(t, err)
+		require.Empty(t, rs.CommitSHA)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	if !gitAvailable() {
+		t.Skip("git is not available")
+	}
+
+	ctx := context.Background()
+
+	t.Run("clean worktree with upstream", func(t *testing.T) {
+		remoteDir := t.TempDir()
+		runGit(t, remoteDir, "init", "--bare")
+
+		testDir := t.TempDir()
+		runGit(t, testDir, "init")
+		runGit(t, testDir, "config", "user.email", "test@test.com")
+		runGit(t, testDir, "config", "user.name", "Test User")
+		runGit(t, testDir, "checkout", "-b", "main")
+
+		testFile := filepath.Join(testDir, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("test"), 0o644))
+		runGit(t, testDir, "add", ".")
+		runGit(t, testDir, "commit", "-m", "initial commit")
+
+		runGit(t, testDir, "remote", "add", "origin", remoteDir)
+		runGit(t, testDir, "push", "-u", "origin", "main")
+
+		status, err := Status(ctx, testDir)
+		require.NoError(t, err)
+		require.Equal(t, "main", status.Branch)
+		require.False(t, status.Detached)
+		require.Equal(t, "origin/main", status.Upstream)
+		require.Zero(t, status.Ahead)
+		require.Zero(t, status.Behind)
+		require.Zero(t, status.Staged)
+		require.Zero(t, status.Unstaged)
+		require.Zero(t, status.Untracked)
+		require.False(t, status.Merging)
+		require.False(t, status.Rebasing)
+		require.False(t, status.CherryPicking)
+	})
+
+	t.Run("ahead of upstream with pending changes", func(t *testing.T) {
+		remoteDir := t.TempDir()
+		runGit(t, remoteDir, "init", "--bare")
+
+		testDir := t.TempDir()
+		runGit(t, testDir, "init")
+		runGit(t, testDir, "config", "user.email", "test@test.com")
+		runGit(t, testDir, "config", "user.name", "Test User")
+		runGit(t, testDir, "checkout", "-b", "main")
+
+		testFile := filepath.Join(testDir, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("test"), 0o644))
+		runGit(t, testDir, "add", ".")
+		runGit(t, testDir, "commit", "-m", "initial commit")
+		runGit(t, testDir, "remote", "add", "origin", remoteDir)
+		runGit(t, testDir, "push", "-u", "origin", "main")
+
+		require.NoError(t, os.WriteFile(testFile, []byte("changed"), 0o644))
+		runGit(t, testDir, "commit", "-am", "second commit")
+
+		require.NoError(t, os.WriteFile(filepath.Join(testDir, "new.txt"), []byte("new"), 0o644))
+
+		status, err := Status(ctx, testDir)
+		require.NoError(t, err)
+		require.Equal(t, 1, status.Ahead)
+		require.Equal(t, 0, status.Behind)
+		require.Equal(t, 1, status.Untracked)
+	})
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		testDir := t.TempDir()
+		runGit(t, testDir, "init")
+		runGit(t, testDir, "config", "user.email", "test@test.com")
+		runGit(t, testDir, "config", "user.name", "Test User")
+
+		testFile := filepath.Join(testDir, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("test"), 0o644))
+		runGit(t, testDir, "add", ".")
+		runGit(t, testDir, "commit", "-m", "initial commit")
+
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = testDir
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		commitHash := string(output[:len(output)-1])
+		runGit(t, testDir, "checkout", commitHash)
+
+		status, err := Status(ctx, testDir)
+		require.NoError(t, err)
+		require.True(t, status.Detached)
+		require.Empty(t, status.Branch)
+		require.NotEmpty(t, status.CommitSHA)
+	})
+
+	t.Run("returns error for non-git directory", func(t *testing.T) {
+		testDir := t.TempDir()
+
+		_, err := Status(ctx, testDir)
+		require.Error(t, err)
+	})
+
+	t.Run("reports merge in progress from a subdirectory", func(t *testing.T) {
+		testDir := t.TempDir()
+		runGit(t, testDir, "init")
+		runGit(t, testDir, "config", "user.email", "test@test.com")
+		runGit(t, testDir, "config", "user.name", "Test User")
+		runGit(t, testDir, "checkout", "-b", "main")
+
+		testFile := filepath.Join(testDir, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("base"), 0o644))
+		runGit(t, testDir, "add", ".")
+		runGit(t, testDir, "commit", "-m", "initial commit")
+		runGit(t, testDir, "checkout", "-b", "feature")
+
+		require.NoError(t, os.WriteFile(testFile, []byte("feature"), 0o644))
+		runGit(t, testDir, "commit", "-am", "feature commit")
+
+		runGit(t, testDir, "checkout", "main")
+		require.NoError(t, os.WriteFile(testFile, []byte("main"), 0o644))
+		runGit(t, testDir, "commit", "-am", "main commit")
+
+		subDir := filepath.Join(testDir, "src", "internal")
+		require.NoError(t, os.MkdirAll(subDir, 0o755))
+
+		mergeCmd := exec.Command("git", "merge", "feature")
+		mergeCmd.Dir = testDir
+		_ = mergeCmd.Run() // expected to conflict
+
+		status, err := Status(ctx, subDir)
+		require.NoError(t, err)
+		require.True(t, status.Merging)
+	})
+}
@@ -1,12 +1,17 @@
 package log
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"runtime/debug"
+	"runtime/pprof"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,10 +24,25 @@ import (
 var (
 	initOnce    sync.Once
 	initialized atomic.Bool
+
+	panicDir atomic.Value // string
+
+	// seenPanics dedupes repeated panics within this process by the hash of
+	// their stack trace, so a crash loop doesn't fill the disk with
+	// identical reports.
+	seenPanics sync.Map // map[string]struct{}
 )
 
 const MaxAgeDays = 30
 
+// panicsSubdir is the directory, relative to Setup's logFile directory, that
+// panic reports are written to.
+const panicsSubdir = "panics"
+
+// panicFilePattern matches the panic report files written by RecoverPanic,
+// for cleanupOldFiles.
+var panicFilePattern = regexp.MustCompile(`^crush-panic-.+\.(log|txt)$`)
+
 func Setup(logFile string, debug bool) {
 	initOnce.Do(func() {
 		// Create a process-specific log file name to avoid conflicts between multiple processes
@@ -55,20 +75,38 @@ func Setup(logFile string, debug bool) {
 
 		slog.SetDefault(slog.New(logger))
 		initialized.Store(true)
+
+		pDir := filepath.Join(dir, panicsSubdir)
+		if err := os.MkdirAll(pDir, 0o755); err == nil {
+			panicDir.Store(pDir)
+			cleanupOldFiles(pDir, panicFilePattern)
+		}
 	})
 }
 
+// PanicDir returns the directory panic reports are written to, so the UI can
+// tell the user exactly where to find a crash report. It returns "" until
+// Setup has run.
+func PanicDir() string {
+	dir, _ := panicDir.Load().(string)
+	return dir
+}
+
 func cleanupOldProcessLogs(logsDir, baseName, ext string) {
-	// Find all process log files matching pattern <basename>-<pid>.<ext>
-	files, err := os.ReadDir(logsDir)
+	// Match pattern like "crush-12345.log"
+	pattern := regexp.MustCompile(fmt.Sprintf(`^%s-(\d+)%s$`, regexp.QuoteMeta(baseName), regexp.QuoteMeta(ext)))
+	cleanupOldFiles(logsDir, pattern)
+}
+
+// cleanupOldFiles removes files directly inside dir whose name matches
+// pattern and whose modification time is older than MaxAgeDays.
+func cleanupOldFiles(dir string, pattern *regexp.Regexp) {
+	files, err := os.ReadDir(dir)
 	if err != nil {
-		// Log directory might not exist yet
+		// Directory might not exist yet
 		return
 	}
 
-	// Match pattern like "crush-12345.log"
-	pattern := regexp.MustCompile(fmt.Sprintf(`^%s-(\d+)%s$`, regexp.QuoteMeta(baseName), regexp.QuoteMeta(ext)))
-
 	cutoffTime := time.Now().AddDate(0, 0, -MaxAgeDays)
 
 	for _, file := range files {
@@ -80,7 +118,7 @@ func cleanupOldProcessLogs(logsDir, baseName, ext string) {
 			continue
 		}
 
-		filePath := filepath.Join(logsDir, file.Name())
+		filePath := filepath.Join(dir, file.Name())
 		info, err := os.Stat(filePath)
 		if err != nil {
 			continue
@@ -89,7 +127,7 @@ func cleanupOldProcessLogs(logsDir, baseName, ext string) {
 		// Check if file is older than MaxAgeDays
 		if info.ModTime().Before(cutoffTime) {
 			if err := os.Remove(filePath); err == nil {
-				slog.Info("Cleaned up old process log file",
+				slog.Info("Cleaned up old log file",
 					"file", file.Name(),
 					"age_days", int(time.Since(info.ModTime()).Hours()/24),
 				)
@@ -102,27 +140,98 @@ func Initialized() bool {
 	return initialized.Load()
 }
 
+// RecoverPanic recovers a panic, if one is in flight, logs it through the
+// event package, and writes a JSON report (matching the slog record schema)
+// plus a human-readable .txt sibling into PanicDir. Repeated panics with the
+// same stack trace are only reported once per process. cleanup, if non-nil,
+// always runs after a panic is recovered.
 func RecoverPanic(name string, cleanup func()) {
-	if r := recover(); r != nil {
-		event.Error(r, "panic", true, "name", name)
+	r := recover()
+	if r == nil {
+		return
+	}
 
-		// Create a timestamped panic log file
-		timestamp := time.Now().Format("20060102-150405")
-		filename := fmt.Sprintf("crush-panic-%s-%s.log", name, timestamp)
+	event.Error(r, "panic", true, "name", name)
 
-		file, err := os.Create(filename)
-		if err == nil {
-			defer file.Close()
+	stack := debug.Stack()
+	if !alreadyReported(stack) {
+		writePanicReport(name, r, stack)
+	}
+
+	if cleanup != nil {
+		cleanup()
+	}
+}
+
+// alreadyReported reports whether stack has already been written this
+// process, recording it for future calls if not.
+func alreadyReported(stack []byte) bool {
+	key := stackFingerprint(stack)
+	_, loaded := seenPanics.LoadOrStore(key, struct{}{})
+	return loaded
+}
+
+// stackFingerprint hashes stack with its leading "goroutine N [status]:"
+// header line stripped, since a panic recurring in a crash loop runs in a
+// fresh goroutine every time and so gets a different N on each occurrence
+// even though the rest of the trace, and the bug, are identical.
+func stackFingerprint(stack []byte) string {
+	if i := bytes.IndexByte(stack, '\n'); i >= 0 {
+		stack = stack[i+1:]
+	}
+	sum := sha256.Sum256(stack)
+	return hex.EncodeToString(sum[:])
+}
+
+// writePanicReport writes a JSON report and a human-readable .txt sibling
+// describing a recovered panic into PanicDir (or the working directory, if
+// Setup was never called).
+func writePanicReport(name string, recovered any, stack []byte) {
+	dir := PanicDir()
+	if dir == "" {
+		dir = "."
+	}
 
-			// Write panic information and stack trace
-			fmt.Fprintf(file, "Panic in %s: %v\n\n", name, r)
-			fmt.Fprintf(file, "Time: %s\n\n", time.Now().Format(time.RFC3339))
-			fmt.Fprintf(file, "Stack Trace:\n%s\n", debug.Stack())
+	var goroutines bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&goroutines, 2)
 
-			// Execute cleanup function if provided
-			if cleanup != nil {
-				cleanup()
+	var gitCommit string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				gitCommit = setting.Value
+				break
 			}
 		}
 	}
+
+	base := fmt.Sprintf("crush-panic-%s-%s", name, time.Now().Format("20060102-150405"))
+
+	if f, err := os.Create(filepath.Join(dir, base+".log")); err == nil {
+		logger := slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelError}))
+		logger.Error("panic recovered",
+			"name", name,
+			"recovered", fmt.Sprint(recovered),
+			"stack", string(stack),
+			"goroutines", goroutines.String(),
+			"goos", runtime.GOOS,
+			"goarch", runtime.GOARCH,
+			"go_version", runtime.Version(),
+			"git_commit", gitCommit,
+		)
+		f.Close()
+	}
+
+	if f, err := os.Create(filepath.Join(dir, base+".txt")); err == nil {
+		fmt.Fprintf(f, "Panic in %s: %v\n\n", name, recovered)
+		fmt.Fprintf(f, "Time: %s\n", time.Now().Format(time.RFC3339))
+		fmt.Fprintf(f, "OS/Arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		fmt.Fprintf(f, "Go version: %s\n", runtime.Version())
+		if gitCommit != "" {
+			fmt.Fprintf(f, "Git commit: %s\n", gitCommit)
+		}
+		fmt.Fprintf(f, "\nStack Trace:\n%s\n", stack)
+		fmt.Fprintf(f, "\nGoroutines:\n%s\n", goroutines.String())
+		f.Close()
+	}
 }
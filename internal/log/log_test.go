@@ -0,0 +1,49 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackFingerprint(t *testing.T) {
+	t.Run("ignores the goroutine header's varying ID", func(t *testing.T) {
+		a := []byte("goroutine 7 [running]:\nmain.foo(...)\n\t/app/main.go:10 +0x20\n")
+		b := []byte("goroutine 412 [running]:\nmain.foo(...)\n\t/app/main.go:10 +0x20\n")
+
+		require.Equal(t, stackFingerprint(a), stackFingerprint(b))
+	})
+
+	t.Run("differs for genuinely different traces", func(t *testing.T) {
+		a := []byte("goroutine 7 [running]:\nmain.foo(...)\n\t/app/main.go:10 +0x20\n")
+		b := []byte("goroutine 7 [running]:\nmain.bar(...)\n\t/app/other.go:20 +0x30\n")
+
+		require.NotEqual(t, stackFingerprint(a), stackFingerprint(b))
+	})
+}
+
+func TestAlreadyReported(t *testing.T) {
+	stackA := []byte("goroutine 1 [running]:\nmain.foo(...)\n\t/app/main.go:10 +0x20\n")
+	stackB := []byte("goroutine 2 [running]:\nmain.foo(...)\n\t/app/main.go:10 +0x20\n")
+	stackC := []byte("goroutine 3 [running]:\nmain.bar(...)\n\t/app/other.go:20 +0x30\n")
+
+	require.False(t, alreadyReported(stackA), "first occurrence should not be marked as already reported")
+	require.True(t, alreadyReported(stackB), "same panic recurring in a new goroutine should dedupe")
+	require.False(t, alreadyReported(stackC), "a genuinely different panic should not be deduped")
+}
+
+func TestSetupCreatesPanicDir(t *testing.T) {
+	dir := t.TempDir()
+	Setup(filepath.Join(dir, "crush.log"), false)
+
+	require.True(t, Initialized())
+
+	panicDir := PanicDir()
+	require.Equal(t, filepath.Join(dir, panicsSubdir), panicDir)
+
+	info, err := os.Stat(panicDir)
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+}